@@ -285,3 +285,284 @@ func udivisible(n uint, c int64) udivisibleData {
 		max: max,
 	}
 }
+
+// For signed integers, a similar approach works, with a little extra
+// complication to handle the sign of x.
+//
+// Again write c = d0 * 2^k with d0 odd, and let m be the inverse of
+// d0 modulo 2^n, exactly as in the unsigned case.  Let t = x/d0 be the
+// (real-valued) quotient; x*m mod 2^n equals t's n-bit two's complement
+// representation whenever d0 | x, regardless of the sign of x.
+//
+// When c | x, t = 2^k * (x/c), and the quotient x/c ranges over two
+// disjoint bands depending on the sign of x: for x >= 0 it covers
+// [0, qp] where qp = ⎣(2^(n-1)-1)/c⎦, the number of positive multiples
+// of c representable in an n-bit signed integer; for x < 0 it covers
+// [-qn, -1] where qn = ⎣2^(n-1)/c⎦, so as an unsigned n-bit value
+// x*m mod 2^n sits just below 2^n rather than just above 0.  The two bands are not adjacent: a plain bias can't
+// merge them into the single [0, qp+qn] window the ZRU theorem from
+// the unsigned case needs, because any bias that isn't itself a
+// multiple of 2^k perturbs the very k trailing zero bits the rotate
+// is testing.  Biasing by qn*2^k (a multiple of 2^k) before rotating
+// avoids that: it leaves the low k bits untouched and still slides
+// the negative band up to meet the positive one.
+//
+// x is a multiple of c iff RotRight(x*m + qn*2^k, k) <= qp+qn  (unsigned compare)
+//
+// Negative c is handled by negating c, since x%c == x%(-c).  There is
+// no positive representation of c == -2^(n-1), so that case is rejected.
+
+// sdivisibleOK reports whether we should strength reduce a n-bit signed divisibility check by c.
+func sdivisibleOK(n uint, c int64) bool {
+	if c == -1<<(n-1) {
+		// Doesn't work for the most negative n-bit value; -c isn't representable.
+		return false
+	}
+	if c < 0 {
+		c = -c
+	}
+	// Doesn't work for 0.
+	// Don't use for powers of 2.
+	return c&(c-1) != 0
+}
+
+type sdivisibleData struct {
+	k   int64  // trailingZeros(c)
+	m   uint64 // m * (c>>k) mod 2^n == 1 multiplicative inverse of odd portion modulo 2^n
+	a   uint64 // bias to add before rotating, ⎣2^(n-1)/c⎦ << k
+	max uint64 // ⎣(2^(n-1)-1)/c⎦ + ⎣2^(n-1)/c⎦, max value after rotate for divisibility
+}
+
+func sdivisible(n uint, c int64) sdivisibleData {
+	if c < 0 {
+		c = -c
+	}
+	d := uint64(c)
+
+	k := bits.TrailingZeros64(d)
+	d0 := d >> uint(k) // the odd portion of the divisor
+
+	mask := ^uint64(0) >> (64 - n)
+
+	// Calculate the multiplicative inverse via Newton's method,
+	// exactly as in udivisible above.
+	m := d0            // initial guess correct to 3-bits d0*d0 mod 8 == 1
+	m = m * (2 - m*d0) // 6-bits
+	m = m * (2 - m*d0) // 12-bits
+	m = m * (2 - m*d0) // 24-bits
+	m = m * (2 - m*d0) // 48-bits
+	m = m * (2 - m*d0) // 96-bits >= 64-bits
+	m = m & mask
+
+	// qp = ⎣(2^(n-1)-1)/c⎦, the number of positive multiples of c
+	// representable in an n-bit signed integer.
+	// qn = ⎣2^(n-1)/c⎦, the number of negative multiples of c
+	// representable in an n-bit signed integer.
+	qp := (mask >> 1) / d
+	qn := (mask>>1 + 1) / d
+
+	return sdivisibleData{
+		k:   int64(k),
+		m:   m,
+		a:   qn << uint(k),
+		max: qp + qn,
+	}
+}
+
+// The strategies above compute x%c as x - ⎣x/c⎦*c, reusing the magic
+// multiply-high already needed for division.  When only the remainder
+// is wanted (as in hashing or bucket selection), Lemire, Kaser & Kurz
+// ("Faster Remainder by Direct Computation", 2019) show the remainder
+// can be had from a single wide multiply instead, with no following
+// subtract.
+//
+// Pick M = ⎡2^(2n)/c⎤, a constant of at most 2n bits.  For any
+// 0 <= x < 2^n, compute the 2n-bit product
+//   lowbits = (x * M) mod 2^(2n)
+// and then the remainder is the high n bits of lowbits * c:
+//   x % c = ⎣lowbits * c / 2^(2n)⎦
+//
+// Intuitively, x*M/2^(2n) approximates x/c, so lowbits/2^(2n)
+// approximates the fractional part {x/c}, and multiplying that
+// fraction back by c recovers the remainder.  M >= 2^(2n)/c guarantees
+// the approximation never rounds the quotient down, which is what
+// makes the high bits of lowbits*c exact for every x in range, not
+// just on average.
+//
+// On a machine with an n x n -> 2n multiply, this lowers to:
+//   lowbits = low_2n(x * M)      // one n x n -> 2n multiply (two if M has 2n bits)
+//   rem     = high_n(lowbits * c) // one 2n x n -> n multiply, keeping the top n bits
+// For n=32 this is a 32x32->64 multiply to form lowbits, followed by a
+// 64x32->96 multiply of which only bits [64:96] are kept.  For n=64,
+// M no longer fits in one word, so forming lowbits and the final
+// product both take a couple of 64x64->128 multiplies and adds.
+
+// umodOK reports whether we should strength reduce a n-bit modulus by c.
+func umodOK(n uint, c int64) bool {
+	// Convert from ConstX auxint values to the real uint64 constant they represent.
+	d := uint64(c) << (64 - n) >> (64 - n)
+
+	// Doesn't work for 0.
+	// Don't use for powers of 2 (handled elsewhere using a mask).
+	return d&(d-1) != 0
+}
+
+type umodData struct {
+	hi uint64 // high 64 bits of M = ⎡2^(2n)/c⎤ (zero whenever 2n <= 64)
+	lo uint64 // low 64 bits of M
+}
+
+// umod computes the constant needed to strength reduce an unsigned n-bit
+// modulus by the constant uint64(c), using Lemire's direct remainder method.
+// The return value satisfies for all 0 <= x < 2^n
+//  x % uint64(c) = high_n(low_2n(x*M) * uint64(c))
+// where M = hi:lo is the 2n-bit constant ⎡2^(2n)/c⎤.
+func umod(n uint, c int64) umodData {
+	// Convert from ConstX auxint values to the real uint64 constant they represent.
+	d := uint64(c) << (64 - n) >> (64 - n)
+
+	C := new(big.Int).SetUint64(d)
+	M := big.NewInt(1)
+	M.Lsh(M, 2*n)           // 2^(2n)
+	M.Add(M, C)             // 2^(2n)+c
+	M.Sub(M, big.NewInt(1)) // 2^(2n)+c-1
+	M.Div(M, C)             // ⎡2^(2n)/c⎤
+
+	mask64 := new(big.Int).SetUint64(^uint64(0))
+	lo := new(big.Int).And(M, mask64).Uint64()
+	hi := new(big.Int).Rsh(M, 64).Uint64()
+
+	return umodData{hi: hi, lo: lo}
+}
+
+// For signed division, c is required positive, exactly as for smagic
+// above (a negative c is handled by the caller via x%c == x%(-c)).
+// Go's % truncates toward zero, so x%c and (-x)%c are negatives of
+// each other; the direct-remainder multiply itself only ever needs to
+// run on a nonnegative magnitude, so we reuse the same M as umod and
+// apply it to |x| instead of x:
+//   r = high_n(low_2n(|x|*M) * c)  // same wide multiply as umod, on the magnitude of x
+//   if x < 0 { r = -r }            // restore the sign truncation dropped
+// |x| is computed as the two's complement negation of x's bit
+// pattern, which is exact even for x = math.MinInt64: its magnitude
+// 2^63 is still representable unsigned.  One wide multiply plus a
+// sign fixup, no division and no second multiply.
+
+// smodOK reports whether we should strength reduce a n-bit signed modulus by c.
+func smodOK(n uint, c int64) bool {
+	return smagicOK(n, c)
+}
+
+type smodData struct {
+	hi uint64 // high 64 bits of M = ⎡2^(2n)/c⎤ (zero whenever 2n <= 64)
+	lo uint64 // low 64 bits of M
+}
+
+// smod computes the constant needed to strength reduce a signed n-bit
+// modulus by the positive constant c, using the same direct-remainder
+// method as umod; the sign correction is applied by the caller.
+func smod(n uint, c int64) smodData {
+	u := umod(n, c)
+	return smodData{hi: u.hi, lo: u.lo}
+}
+
+// umagic above picks m to be the smallest value satisfying c*m >= 2^(n+s),
+// which forces m to n+1 bits and fans out into the three Case 1/2/3
+// lowerings just to keep every intermediate to n bits.  Robison's
+// round-up reciprocal (Warren §10-9) avoids the split entirely by
+// rounding m up one step further:
+//   m = ⎣2^(n+s)/c⎦ + 1
+// (floor-plus-one, not the ceiling umagic uses), for some shift s.
+// Unlike umagic's s = ⎡log2(c)⎤, there's no single formula for the
+// smallest working s here, and s = ⎡log2(c)⎤ never works: it makes m
+// exceed n bits.  m grows monotonically with s, so the search for an
+// s where m fits in n bits starts at s = 1 and increases (s = 0 is
+// not tried).
+// Provided m fits in n bits, ⎣x/c⎦ = high_n(x*m) >> s is exact for
+// every 0 <= x < 2^n iff the accumulated rounding error from the
+// floor-plus-one never reaches a full unit over the largest multiply:
+// writing e = m*c - 2^(n+s) (the amount m*c overshoots by) and with
+// qmax = ⎣(2^n-1)/c⎦ and rmax = (2^n-1) - c*qmax the quotient and
+// remainder of the largest representable x, the worst case is exactly
+// max(qmax*e + rmax*m, (qmax-1)*e + (c-1)*m) < 2^(n+s); the first term
+// is the error at the largest x, the second at the largest x one
+// period of c earlier (where the remainder term (c-1)*m is largest).
+// We try increasing s until this holds or until s would exceed n, at
+// which point we fall back to the three-case umagic above; some
+// divisors (e.g. c=7 at n=8) have no working s at all.
+
+// umagicRoundUpOK reports whether we should strength reduce a n-bit divide
+// by c using the single-multiply-single-shift round-up form.
+func umagicRoundUpOK(n uint, c int64) bool {
+	d := uint64(c) << (64 - n) >> (64 - n)
+	if d&(d-1) == 0 {
+		// Doesn't work for 0.  Don't use for powers of 2.
+		return false
+	}
+	_, ok := umagicRoundUp1(n, d)
+	return ok
+}
+
+type umagicRoundUpData struct {
+	s int64  // shift, found by search; smallest s >= 1 for which the round-up form is exact and m fits in n bits
+	m uint64 // ⎣2^(n+s)/c⎦ + 1, guaranteed to fit in n bits
+}
+
+// umagicRoundUp computes the constants needed to strength reduce an
+// unsigned n-bit divide by the constant uint64(c) using Robison's
+// round-up reciprocal.  The return values satisfy for all 0 <= x < 2^n
+//  floor(x / uint64(c)) = high_n(x * m) >> s
+// Callers must check umagicRoundUpOK first; some divisors have no s
+// for which the round-up form is exact, and fall back to the
+// three-case umagic above.
+func umagicRoundUp(n uint, c int64) umagicRoundUpData {
+	d := uint64(c) << (64 - n) >> (64 - n)
+	data, ok := umagicRoundUp1(n, d)
+	if !ok {
+		panic("umagicRoundUp: no s makes the round-up form exact; check umagicRoundUpOK first")
+	}
+	return data
+}
+
+// umagicRoundUp1 does the actual work for umagicRoundUp, searching
+// increasing s for the smallest one that both fits m in n bits and
+// rounds exactly, or giving up (ok == false) once s would exceed n.
+func umagicRoundUp1(n uint, d uint64) (data umagicRoundUpData, ok bool) {
+	C := new(big.Int).SetUint64(d)
+	maxX := new(big.Int).Lsh(big.NewInt(1), n)
+	maxX.Sub(maxX, big.NewInt(1)) // 2^n - 1
+	qmax := new(big.Int).Div(maxX, C)
+	rmax := new(big.Int).Mod(maxX, C)
+	qmaxMinus1 := new(big.Int).Sub(qmax, big.NewInt(1))
+	cMinus1 := new(big.Int).Sub(C, big.NewInt(1))
+
+	for s := 1; s <= int(n); s++ {
+		N := new(big.Int).Lsh(big.NewInt(1), n+uint(s)) // 2^(n+s)
+		M := new(big.Int).Div(N, C)                     // ⎣2^(n+s)/c⎦
+		M.Add(M, big.NewInt(1))                         // ⎣2^(n+s)/c⎦ + 1
+		if M.BitLen() > int(n) {
+			continue
+		}
+		e := new(big.Int).Mul(M, C)
+		e.Sub(e, N) // m*c - 2^(n+s), the per-unit rounding error
+
+		// Worst-case accumulated error occurs at the largest x (qmax
+		// full periods of c plus a remainder of rmax) or one period
+		// earlier (qmax-1 full periods plus a remainder of c-1,
+		// whichever is larger); the round-up form is exact for every
+		// 0 <= x < 2^n iff neither pushes the result past 2^(n+s).
+		worst := new(big.Int).Mul(qmax, e)
+		worst.Add(worst, new(big.Int).Mul(rmax, M))
+		if qmax.Sign() > 0 {
+			alt := new(big.Int).Mul(qmaxMinus1, e)
+			alt.Add(alt, new(big.Int).Mul(cMinus1, M))
+			if alt.Cmp(worst) > 0 {
+				worst = alt
+			}
+		}
+		if worst.Cmp(N) < 0 {
+			return umagicRoundUpData{s: int64(s), m: M.Uint64()}, true
+		}
+	}
+	return umagicRoundUpData{}, false
+}
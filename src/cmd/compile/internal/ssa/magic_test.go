@@ -0,0 +1,242 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// signed reinterprets the low n bits of v as a signed n-bit integer,
+// sign-extended to int64 -- the same ConstX auxint convention magic.go
+// uses for unsigned truncation, mirrored here for the signed case.
+func signed(n uint, v uint64) int64 {
+	return int64(v<<(64-n)) >> (64 - n)
+}
+
+// rotr is an n-bit unsigned right rotation, the brute-force oracle's
+// stand-in for the RotRight that sdivisible's rewrite rule would emit.
+func rotr(x uint64, k, n uint) uint64 {
+	mask := ^uint64(0) >> (64 - n)
+	x &= mask
+	if k == 0 {
+		return x
+	}
+	return ((x >> k) | (x << (n - k))) & mask
+}
+
+func TestSdivisibleExhaustive(t *testing.T) {
+	for n := uint(4); n <= 9; n++ {
+		mask := ^uint64(0) >> (64 - n)
+		for craw := uint64(0); craw <= mask; craw++ {
+			c := signed(n, craw)
+			if !sdivisibleOK(n, c) {
+				continue
+			}
+			d := sdivisible(n, c)
+			for xraw := uint64(0); xraw <= mask; xraw++ {
+				x := signed(n, xraw)
+				y := (xraw * d.m) & mask
+				got := rotr(y+d.a, uint(d.k), n) <= d.max
+				want := x%c == 0
+				if got != want {
+					t.Fatalf("sdivisible(%d, %d): x=%d got %v want %v", n, c, x, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSdivisibleSampled(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []uint{16, 32, 64} {
+		mask := ^uint64(0) >> (64 - n)
+		for i := 0; i < 2000; i++ {
+			c := signed(n, rng.Uint64())
+			if !sdivisibleOK(n, c) {
+				continue
+			}
+			d := sdivisible(n, c)
+			for j := 0; j < 20; j++ {
+				xraw := rng.Uint64()
+				x := signed(n, xraw)
+				y := (xraw * d.m) & mask
+				got := rotr(y+d.a, uint(d.k), n) <= d.max
+				want := x%c == 0
+				if got != want {
+					t.Fatalf("sdivisible(%d, %d): x=%d got %v want %v", n, c, x, got, want)
+				}
+			}
+		}
+	}
+}
+
+// wideM reconstructs the 2n-bit constant M = hi:lo as a big.Int, the
+// form umod and smod hand back for n > 32 where M overflows one word.
+func wideM(hi, lo uint64) *big.Int {
+	m := new(big.Int).SetUint64(hi)
+	m.Lsh(m, 64)
+	m.Or(m, new(big.Int).SetUint64(lo))
+	return m
+}
+
+// directRemainder applies the Lemire/Kaser/Kurz direct-remainder
+// multiply to the unsigned magnitude x, the same computation umod's
+// doc comment specifies: x % c = ⎣low_2n(x*M) * c / 2^(2n)⎦.
+func directRemainder(n uint, x, c uint64, M *big.Int) uint64 {
+	mod2n := new(big.Int).Lsh(big.NewInt(1), 2*n)
+	lowbits := new(big.Int).SetUint64(x)
+	lowbits.Mul(lowbits, M)
+	lowbits.Mod(lowbits, mod2n)
+	lowbits.Mul(lowbits, new(big.Int).SetUint64(c))
+	lowbits.Rsh(lowbits, 2*n)
+	return lowbits.Uint64()
+}
+
+func TestUmodExhaustive(t *testing.T) {
+	for n := uint(4); n <= 9; n++ {
+		mask := ^uint64(0) >> (64 - n)
+		for craw := uint64(0); craw <= mask; craw++ {
+			if !umodOK(n, int64(craw)) {
+				continue
+			}
+			d := umod(n, int64(craw))
+			M := wideM(d.hi, d.lo)
+			for x := uint64(0); x <= mask; x++ {
+				got := directRemainder(n, x, craw, M)
+				want := x % craw
+				if got != want {
+					t.Fatalf("umod(%d, %d): x=%d got %d want %d", n, craw, x, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSmodExhaustive(t *testing.T) {
+	for n := uint(4); n <= 9; n++ {
+		mask := ^uint64(0) >> (64 - n)
+		for craw := uint64(1); craw <= mask; craw++ {
+			c := signed(n, craw)
+			if !smodOK(n, c) {
+				continue
+			}
+			d := smod(n, c)
+			M := wideM(d.hi, d.lo)
+			for xraw := uint64(0); xraw <= mask; xraw++ {
+				x := signed(n, xraw)
+				ux := xraw
+				neg := x < 0
+				if neg {
+					ux = (-xraw) & mask // two's complement magnitude
+				}
+				r := int64(directRemainder(n, ux, uint64(c), M))
+				if neg {
+					r = -r
+				}
+				want := x % c
+				if r != want {
+					t.Fatalf("smod(%d, %d): x=%d got %d want %d", n, c, x, r, want)
+				}
+			}
+		}
+	}
+}
+
+func TestUmodSmodSampled(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []uint{16, 32, 64} {
+		mask := ^uint64(0) >> (64 - n)
+		for i := 0; i < 500; i++ {
+			craw := rng.Uint64()&mask | 1 // avoid 0; parity doesn't matter for umod/smod
+			if !umodOK(n, int64(craw)) {
+				continue
+			}
+			ud := umod(n, int64(craw))
+			uM := wideM(ud.hi, ud.lo)
+
+			c := signed(n, craw)
+			var sM *big.Int
+			if smodOK(n, c) {
+				sd := smod(n, c)
+				sM = wideM(sd.hi, sd.lo)
+			}
+
+			for j := 0; j < 20; j++ {
+				xraw := rng.Uint64() & mask
+				got := directRemainder(n, xraw, craw, uM)
+				want := xraw % craw
+				if got != want {
+					t.Fatalf("umod(%d, %d): x=%d got %d want %d", n, craw, xraw, got, want)
+				}
+
+				if sM != nil {
+					x := signed(n, xraw)
+					ux := xraw
+					neg := x < 0
+					if neg {
+						ux = (-xraw) & mask
+					}
+					r := int64(directRemainder(n, ux, uint64(c), sM))
+					if neg {
+						r = -r
+					}
+					wantS := x % c
+					if r != wantS {
+						t.Fatalf("smod(%d, %d): x=%d got %d want %d", n, c, x, r, wantS)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestUmagicRoundUpExhaustive(t *testing.T) {
+	for n := uint(4); n <= 9; n++ {
+		mask := ^uint64(0) >> (64 - n)
+		for craw := uint64(0); craw <= mask; craw++ {
+			if !umagicRoundUpOK(n, int64(craw)) {
+				continue
+			}
+			d := umagicRoundUp(n, int64(craw))
+			for x := uint64(0); x <= mask; x++ {
+				hi := new(big.Int).SetUint64(x)
+				hi.Mul(hi, new(big.Int).SetUint64(d.m))
+				hi.Rsh(hi, n)
+				got := hi.Uint64() >> uint(d.s)
+				want := x / craw
+				if got != want {
+					t.Fatalf("umagicRoundUp(%d, %d): x=%d got %d want %d", n, craw, x, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestUmagicRoundUpSampled(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []uint{16, 32, 64} {
+		mask := ^uint64(0) >> (64 - n)
+		for i := 0; i < 2000; i++ {
+			craw := rng.Uint64() & mask
+			if !umagicRoundUpOK(n, int64(craw)) {
+				continue
+			}
+			d := umagicRoundUp(n, int64(craw))
+			for j := 0; j < 20; j++ {
+				x := rng.Uint64() & mask
+				hi := new(big.Int).SetUint64(x)
+				hi.Mul(hi, new(big.Int).SetUint64(d.m))
+				hi.Rsh(hi, n)
+				got := hi.Uint64() >> uint(d.s)
+				want := x / craw
+				if got != want {
+					t.Fatalf("umagicRoundUp(%d, %d): x=%d got %d want %d", n, craw, x, got, want)
+				}
+			}
+		}
+	}
+}